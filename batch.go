@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWorkers 批量筛选的默认并发worker数
+	DefaultWorkers = 4
+
+	// LixingerRateLimit 理杏仁免费版大约每秒5次请求的限额
+	LixingerRateLimit = 5
+)
+
+// parseCodes 解析-codes参数：逗号分隔的代码列表，或@文件路径（每行一个代码）
+func parseCodes(codes string) ([]string, error) {
+	if strings.HasPrefix(codes, "@") {
+		content, err := ioutil.ReadFile(codes[1:])
+		if err != nil {
+			return nil, fmt.Errorf("读取股票代码文件出错：%w", err)
+		}
+		var list []string
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" {
+				list = append(list, line)
+			}
+		}
+		return list, nil
+	}
+
+	var list []string
+	for _, code := range strings.Split(codes, ",") {
+		code = strings.TrimSpace(code)
+		if code != "" {
+			list = append(list, code)
+		}
+	}
+	return list, nil
+}
+
+// rateLimiter 以固定间隔放行请求，用于限制对数据源的并发调用频率
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+func newRateLimiter(perSecond int) *rateLimiter {
+	if perSecond <= 0 {
+		perSecond = LixingerRateLimit
+	}
+	return &rateLimiter{ticker: time.NewTicker(time.Second / time.Duration(perSecond))}
+}
+
+// wait 对nil limiter也安全，不限流直接放行
+func (r *rateLimiter) wait() {
+	if r == nil {
+		return
+	}
+	<-r.ticker.C
+}
+
+// RunBatch 用worker池并发筛选一批股票代码，并将结果写入report
+func RunBatch(data DataSource, codes []string, workers int, startDate string, bondYield, safetyMargin float64, years int, out string, riskKeywords []string) error {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	jobs := make(chan string, len(codes))
+	for _, code := range codes {
+		jobs <- code
+	}
+	close(jobs)
+
+	results := make([]ScreenResult, 0, len(codes))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for code := range jobs {
+				result, err := screenOne(data, code, startDate, bondYield, safetyMargin, years, riskKeywords)
+				if err != nil {
+					fmt.Printf("%s 筛选出错：%v\n", code, err)
+					continue
+				}
+				mu.Lock()
+				results = append(results, result)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Code < results[j].Code })
+
+	fmt.Printf("批量筛选完成，共 %d/%d 只股票获取到结果\n", len(results), len(codes))
+
+	if out == "" {
+		for _, r := range results {
+			status := "合格"
+			if !r.Passed {
+				status = "不合格：" + strings.Join(r.FailedRules, "、")
+			}
+			fmt.Printf("%s %s %s\n", r.Code, r.Name, status)
+		}
+		return nil
+	}
+
+	if strings.HasSuffix(out, ".json") {
+		return writeJSONReport(out, results)
+	}
+	return writeCSVReport(out, results)
+}
+
+func writeJSONReport(path string, results []ScreenResult) error {
+	b, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("结果序列化出错：%w", err)
+	}
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		return fmt.Errorf("写入结果文件出错：%w", err)
+	}
+	return nil
+}
+
+var csvHeader = []string{
+	"code", "name", "industry", "PE", "PE_pos10", "PB", "PB_pos10",
+	"passed_rules", "failed_rules", "intrinsic_value", "margin_of_safety", "risk_titles",
+}
+
+func writeCSVReport(path string, results []ScreenResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建结果文件出错：%w", err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	if err := w.Write(csvHeader); err != nil {
+		return fmt.Errorf("写入表头出错：%w", err)
+	}
+	for _, r := range results {
+		row := []string{
+			r.Code, r.Name, r.Industry,
+			strconv.FormatFloat(r.Pe, 'f', 2, 64),
+			strconv.FormatFloat(r.PePos10, 'f', 4, 64),
+			strconv.FormatFloat(r.Pb, 'f', 2, 64),
+			strconv.FormatFloat(r.PbPos10, 'f', 4, 64),
+			strings.Join(r.PassedRules, "|"),
+			strings.Join(r.FailedRules, "|"),
+			strconv.FormatFloat(r.IntrinsicValue, 'f', 2, 64),
+			strconv.FormatFloat(r.MarginOfSafety, 'f', 2, 64),
+			strings.Join(r.RiskTitles, "|"),
+		}
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("写入结果行出错：%w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultCacheDir 是理杏仁响应缓存的默认根目录
+	DefaultCacheDir = ".chosestock/cache"
+
+	// DefaultFundamentalTTL 是基本面及每股指标缓存的默认有效期
+	DefaultFundamentalTTL = 24 * time.Hour
+
+	// DefaultIndustryTTL 是年度经营数据缓存的默认有效期，年报数据很少变化
+	DefaultIndustryTTL = 90 * 24 * time.Hour
+)
+
+// Cache 以 <dir>/<endpoint>/<code>_<date>.json 的形式缓存理杏仁的原始响应，
+// 用于减少对免费额度的消耗。并发安全，可在多个worker间共享。
+type Cache struct {
+	Dir     string
+	Refresh bool
+
+	// FundamentalTTL 是基本面/每股指标缓存的有效期，可通过-cache-ttl配置；
+	// 年度经营数据(industry)变化很少，固定使用DefaultIndustryTTL。
+	FundamentalTTL time.Duration
+
+	hits   int64
+	misses int64
+}
+
+// defaultCacheDir 返回 ~/.chosestock/cache，无法获取用户目录时退化为相对路径
+func defaultCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return DefaultCacheDir
+	}
+	return filepath.Join(home, DefaultCacheDir)
+}
+
+// NewCache 创建一个缓存，dir为空时使用DefaultCacheDir，ttl<=0时使用DefaultFundamentalTTL
+func NewCache(dir string, ttl time.Duration, refresh bool) *Cache {
+	if dir == "" {
+		dir = DefaultCacheDir
+	}
+	if ttl <= 0 {
+		ttl = DefaultFundamentalTTL
+	}
+	return &Cache{Dir: dir, Refresh: refresh, FundamentalTTL: ttl}
+}
+
+func (c *Cache) path(endpoint, code, date string) string {
+	return filepath.Join(c.Dir, endpoint, fmt.Sprintf("%s_%s.json", code, date))
+}
+
+// Get 读取未过期的缓存内容，命中/未命中都会计入统计；Refresh为true时直接返回未命中，不计入统计
+func (c *Cache) Get(endpoint, code, date string, ttl time.Duration) ([]byte, bool) {
+	if c == nil || c.Refresh {
+		return nil, false
+	}
+	p := c.path(endpoint, code, date)
+	info, err := os.Stat(p)
+	if err != nil || time.Since(info.ModTime()) > ttl {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+	content, err := ioutil.ReadFile(p)
+	if err != nil {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddInt64(&c.hits, 1)
+	return content, true
+}
+
+// Set 将原始响应写入缓存
+func (c *Cache) Set(endpoint, code, date string, content []byte) {
+	if c == nil {
+		return
+	}
+	p := c.path(endpoint, code, date)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return
+	}
+	_ = ioutil.WriteFile(p, content, 0644)
+}
+
+// fundamentalTTL 返回基本面/每股指标缓存的有效期，对nil Cache也安全
+func (c *Cache) fundamentalTTL() time.Duration {
+	if c == nil || c.FundamentalTTL <= 0 {
+		return DefaultFundamentalTTL
+	}
+	return c.FundamentalTTL
+}
+
+// Stats 返回累计的缓存命中与未命中次数
+func (c *Cache) Stats() (hits, misses int64) {
+	if c == nil {
+		return 0, 0
+	}
+	return atomic.LoadInt64(&c.hits), atomic.LoadInt64(&c.misses)
+}
@@ -0,0 +1,27 @@
+package main
+
+import "errors"
+
+// ErrUnsupported 表示当前数据源不提供该项数据（例如东方财富没有估值分位数据），
+// 调用方应据此跳过对应检查而非当作致命错误中断整个筛选流程。
+var ErrUnsupported = errors.New("数据源不支持该项数据")
+
+// DataSource 屏蔽具体行情/财务数据提供方的差异，使分析逻辑不依赖某一家
+// 付费接口，也便于在测试中替换为mock实现。
+type DataSource interface {
+	// GetFundamentals 获取指定股票在某一天的估值数据（PE/PB及历史分位）
+	GetFundamentals(code, date string) (FundamentalData, error)
+	// GetFinancialReports 获取指定股票从startDate到endDate的季度财务数据
+	GetFinancialReports(code, startDate, endDate string) ([]IndustryData, error)
+	// GetGrahamMetrics 获取计算格雷厄姆内在价值所需的每股指标
+	GetGrahamMetrics(code, date string) (GrahamMetrics, error)
+}
+
+// GrahamMetrics 计算格雷厄姆内在价值所需的每股指标
+type GrahamMetrics struct {
+	EpsTtm float64 `json:"eps_ttm"`
+	Bps    float64 `json:"bps"`
+	// GrowthRate 是预期年化增长率，取值为0-1的小数（如0.12表示12%），
+	// 与理杏仁API中pe_ttm_pos10等分位类指标同一单位约定，而非整数百分比。
+	GrowthRate float64 `json:"growth_rate"`
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	EastmoneyReportURL  = "https://datacenter-web.eastmoney.com/api/data/v1/get"
+	EastmoneyReportName = "RPT_LICO_FN_CPD"
+	eastmoneyPageSize   = 50
+)
+
+// Eastmoney 使用东方财富公开的季报数据作为理杏仁的免费替代数据源，
+// 不需要token，但目前没有公开的估值分位数据，GetFundamentals/GetGrahamMetrics
+// 均返回ErrUnsupported，调用方应跳过对应检查而不是整体失败。
+type Eastmoney struct{}
+
+func (e *Eastmoney) GetFundamentals(code, date string) (FundamentalData, error) {
+	return FundamentalData{}, fmt.Errorf("东方财富数据源暂不支持估值分位数据，请使用 -source lixinger: %w", ErrUnsupported)
+}
+
+func (e *Eastmoney) GetGrahamMetrics(code, date string) (GrahamMetrics, error) {
+	return GrahamMetrics{}, fmt.Errorf("东方财富数据源暂不支持每股指标数据，请使用 -source lixinger: %w", ErrUnsupported)
+}
+
+// GetFinancialReports 按季度逐个报告期拉取startDate到endDate之间的财务数据，
+// 每个报告期内部按pageNumber/pageSize分页。
+func (e *Eastmoney) GetFinancialReports(code, startDate, endDate string) ([]IndustryData, error) {
+	dates, err := quarterEndDates(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	var reports []IndustryData
+	for _, date := range dates {
+		rows, err := e.fetchQuarterReport(code, date)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, rows...)
+	}
+	return reports, nil
+}
+
+func (e *Eastmoney) fetchQuarterReport(code, date string) ([]IndustryData, error) {
+	var rows []IndustryData
+	pageNumber := 1
+	for {
+		filter := fmt.Sprintf(`(SECURITY_CODE="%s")(REPORTDATE='%s')`, code, date)
+		u := fmt.Sprintf("%s?reportName=%s&columns=ALL&filter=%s&pageNumber=%d&pageSize=%d",
+			EastmoneyReportURL, EastmoneyReportName, url.QueryEscape(filter), pageNumber, eastmoneyPageSize)
+		resp, err := http.Get(u)
+		if err != nil {
+			return nil, fmt.Errorf("东方财富API出错：%w", err)
+		}
+		content, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取数据出错：%w", err)
+		}
+		var d EastmoneyReportResponse
+		if err := json.Unmarshal(content, &d); err != nil {
+			return nil, fmt.Errorf("东方财富数据格式化错误：%w", err)
+		}
+		if !d.Success || d.Result == nil || len(d.Result.Data) == 0 {
+			break
+		}
+		for _, item := range d.Result.Data {
+			rows = append(rows, item.toIndustryData())
+		}
+		if pageNumber >= d.Result.Pages {
+			break
+		}
+		pageNumber++
+	}
+	return rows, nil
+}
+
+// quarterEndDates 返回startDate到endDate之间所有季度末日期（YYYY-MM-DD）
+func quarterEndDates(startDate, endDate string) ([]string, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("起始日期格式错误：%w", err)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("结束日期格式错误：%w", err)
+	}
+	var dates []string
+	quarterEnds := []string{"03-31", "06-30", "09-30", "12-31"}
+	for year := start.Year(); year <= end.Year(); year++ {
+		for _, md := range quarterEnds {
+			d, _ := time.Parse("2006-01-02", fmt.Sprintf("%d-%s", year, md))
+			if d.Before(start) || d.After(end) {
+				continue
+			}
+			dates = append(dates, d.Format("2006-01-02"))
+		}
+	}
+	return dates, nil
+}
+
+// EastmoneyReportItem 对应RPT_LICO_FN_CPD报告中的单条季度经营数据
+type EastmoneyReportItem struct {
+	SecurityCode     string  `json:"SECURITY_CODE"`
+	SecurityNameAbbr string  `json:"SECURITY_NAME_ABBR"`
+	ReportDate       string  `json:"REPORTDATE"`
+	IndustryName     string  `json:"INDUSTRY_NAME"`
+	TotalOperateReve float64 `json:"TOTAL_OPERATE_INCOME"`
+	AccountsRece     float64 `json:"ACCOUNTS_RECE"`
+	Inventory        float64 `json:"INVENTORY"`
+	CurrentRatio     float64 `json:"CURRENTRATIO"`
+}
+
+func (i *EastmoneyReportItem) toIndustryData() IndustryData {
+	return IndustryData{
+		StockCode:   i.SecurityCode,
+		StockCnName: i.SecurityNameAbbr,
+		Date:        i.ReportDate,
+		Q: Q{
+			ProfitStatement: ProfitStatement{Bi: Total{T: i.TotalOperateReve}},
+			BalanceSheet: BalanceSheet{
+				Ar:        Total{T: i.AccountsRece},
+				S:         Total{T: i.Inventory},
+				Tca_tcl_r: Total{T: i.CurrentRatio},
+			},
+		},
+		Industry: Industry{CnName: i.IndustryName},
+	}
+}
+
+type EastmoneyReportResponse struct {
+	Success bool                   `json:"success"`
+	Message string                 `json:"message"`
+	Result  *EastmoneyReportResult `json:"result"`
+}
+
+type EastmoneyReportResult struct {
+	Pages int                   `json:"pages"`
+	Data  []EastmoneyReportItem `json:"data"`
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	AnnouncementURL = "https://np-anotice-stock.eastmoney.com/api/security/ann"
+
+	announcementPageSize = 100
+
+	// RiskNoticeDays 是风险公告筛选回溯的默认天数
+	RiskNoticeDays = 180
+)
+
+// DefaultRiskKeywords 是触发风险公告剔除的默认关键词
+var DefaultRiskKeywords = []string{
+	"处罚", "冻结", "诉讼", "质押", "仲裁",
+	"信用减值", "商誉减值", "重大风险", "退市风险", "持股5%以上股东权益变动",
+}
+
+// AnnouncementItem 是东方财富公告接口返回的单条公告
+type AnnouncementItem struct {
+	Title      string `json:"title"`
+	NoticeDate string `json:"notice_date"`
+}
+
+type AnnouncementData struct {
+	List      []AnnouncementItem `json:"list"`
+	TotalPage int                `json:"total_page"`
+}
+
+type AnnouncementResponse struct {
+	Success bool             `json:"success"`
+	Data    AnnouncementData `json:"data"`
+}
+
+// fetchAnnouncementTitles 间接引用FetchAnnouncementTitles，便于测试中替换为
+// 不访问网络的桩实现。
+var fetchAnnouncementTitles = FetchAnnouncementTitles
+
+// FetchAnnouncementTitles 拉取指定股票最近days天内的公告标题，按页遍历直至
+// 公告日期早于截止日期
+func FetchAnnouncementTitles(code string, days int) ([]string, error) {
+	if days <= 0 {
+		days = RiskNoticeDays
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	var titles []string
+	pageIndex := 1
+	for {
+		u := fmt.Sprintf("%s?sr=-1&page_size=%d&page_index=%d&stock_list=%s&f_node=0&s_node=0",
+			AnnouncementURL, announcementPageSize, pageIndex, code)
+		resp, err := http.Get(u)
+		if err != nil {
+			return nil, fmt.Errorf("东方财富公告API出错：%w", err)
+		}
+		content, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取公告数据出错：%w", err)
+		}
+		var d AnnouncementResponse
+		if err := json.Unmarshal(content, &d); err != nil {
+			return nil, fmt.Errorf("公告数据格式化错误：%w", err)
+		}
+		if len(d.Data.List) == 0 {
+			break
+		}
+
+		reachedCutoff := false
+		for _, item := range d.Data.List {
+			publishDate, perr := time.Parse("2006-01-02 15:04:05", item.NoticeDate)
+			if perr != nil {
+				publishDate, perr = time.Parse("2006-01-02", item.NoticeDate)
+			}
+			if perr == nil && publishDate.Before(cutoff) {
+				reachedCutoff = true
+				break
+			}
+			titles = append(titles, item.Title)
+		}
+		if reachedCutoff || pageIndex >= d.Data.TotalPage {
+			break
+		}
+		pageIndex++
+	}
+	return titles, nil
+}
+
+// RiskNotice 扫描公告标题，命中风险关键词的股票应予以剔除
+func RiskNotice(titles, keywords []string) (passed bool, matched []string, detail string) {
+	if len(keywords) == 0 {
+		keywords = DefaultRiskKeywords
+	}
+	for _, title := range titles {
+		for _, kw := range keywords {
+			if strings.Contains(title, kw) {
+				matched = append(matched, title)
+				break
+			}
+		}
+	}
+	if len(matched) > 0 {
+		return false, matched, fmt.Sprintf("近期公告命中风险关键词，不合格：%s", strings.Join(matched, "；"))
+	}
+	return true, nil, "近期公告未发现风险关键词，通过公告风险筛选"
+}
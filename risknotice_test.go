@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestRiskNotice(t *testing.T) {
+	t.Run("命中关键词时不合格并返回命中标题", func(t *testing.T) {
+		titles := []string{"关于公司股份被司法冻结的公告", "2023年年度报告"}
+		ok, matched, _ := RiskNotice(titles, []string{"冻结"})
+		if ok {
+			t.Fatal("标题命中风险关键词时应判定不合格")
+		}
+		if len(matched) != 1 || matched[0] != titles[0] {
+			t.Fatalf("matched 应只包含命中的标题，实际：%v", matched)
+		}
+	})
+
+	t.Run("未命中任何关键词时合格", func(t *testing.T) {
+		titles := []string{"2023年年度报告", "关于召开股东大会的通知"}
+		ok, matched, _ := RiskNotice(titles, []string{"冻结", "诉讼"})
+		if !ok {
+			t.Fatal("标题未命中风险关键词时应判定合格")
+		}
+		if len(matched) != 0 {
+			t.Fatalf("未命中时matched应为空，实际：%v", matched)
+		}
+	})
+
+	t.Run("未传入关键词时使用默认关键词列表", func(t *testing.T) {
+		titles := []string{"关于公司涉及重大诉讼的公告"}
+		ok, matched, _ := RiskNotice(titles, nil)
+		if ok || len(matched) != 1 {
+			t.Fatalf("空keywords应退化为DefaultRiskKeywords，实际ok=%v matched=%v", ok, matched)
+		}
+	})
+}
@@ -3,12 +3,14 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -21,35 +23,9 @@ const (
 
 	FundomentalURL = "https://open.lixinger.com/api/a/stock/fundamental"
 	IndustryURL    = "https://open.lixinger.com/api/a/stock/fs/industry"
-)
 
-var (
-	bi2017 float64
-	bi2016 float64
-	bi2015 float64
-	bi2014 float64
-
-	ar2017 float64
-	ar2016 float64
-	ar2015 float64
-	ar2014 float64
-
-	s2017 float64
-	s2016 float64
-	s2015 float64
-	s2014 float64
-
-	bigrowth1 float64
-	bigrowth2 float64
-	bigrowth3 float64
-
-	argrowth1 float64
-	argrowth2 float64
-	argrowth3 float64
-
-	sgrowth1 float64
-	sgrowth2 float64
-	sgrowth3 float64
+	// DefaultWindowYears 小熊定理滑动窗口的默认年数
+	DefaultWindowYears = 4
 )
 
 type Lixinger struct {
@@ -59,85 +35,137 @@ type Lixinger struct {
 	EndDate    string   `json:"endDate,omitempty"`
 	Metrics    []string `json:"metrics"`
 	Token      string   `json:"token"`
+
+	Cache   *Cache
+	Limiter *rateLimiter
 }
 
-// 基本面信息，需要具体的日期
-func (l *Lixinger) Fundamental(code string) {
-	l.StockCodes = []string{code}
-	date := time.Now().Format("2006-01-02")
-	str := strings.Split(date, "-")
-	day, _ := strconv.Atoi(str[2])
-	nd := day - 1
-	if nd < 10 {
-		l.Date = fmt.Sprintf("%s-%s-0%s", str[0], str[1], strconv.Itoa(day-1))
-	} else {
-		l.Date = fmt.Sprintf("%s-%s-%s", str[0], str[1], strconv.Itoa(nd))
+// GetFundamentals 从理杏仁拉取指定日期的估值数据，需要付费token
+func (l *Lixinger) GetFundamentals(code, date string) (FundamentalData, error) {
+	if cached, ok := l.Cache.Get("fundamental", code, date, l.Cache.fundamentalTTL()); ok {
+		var d FunResponseData
+		if err := json.Unmarshal(cached, &d); err == nil && len(d.Data) > 0 {
+			return d.Data[0], nil
+		}
 	}
-	// l.Date = time.Now().Format("2006-01-02")
-	l.Metrics = []string{
-		"pb", "pb_pos10", "pb_pos_all",
-		"pe_ttm", "pe_ttm_pos10", "pe_ttm_pos_all",
-		"d_pe_ttm", "d_pe_ttm_pos10", "d_pe_ttm_pos_all",
+
+	req := Lixinger{
+		StockCodes: []string{code},
+		Date:       date,
+		Token:      l.Token,
+		Metrics: []string{
+			"pb", "pb_pos10", "pb_pos_all",
+			"pe_ttm", "pe_ttm_pos10", "pe_ttm_pos_all",
+			"d_pe_ttm", "d_pe_ttm_pos10", "d_pe_ttm_pos_all",
+		},
 	}
-	body := l.Format()
+	body := req.Format()
 	if body == nil {
-		fmt.Println("POST数据格式化错误")
+		return FundamentalData{}, fmt.Errorf("POST数据格式化错误")
 	}
+	l.Limiter.wait()
 	resp, err := http.Post(FundomentalURL, ContentType, body)
-	defer resp.Body.Close()
 	if err != nil {
-		fmt.Println("理杏仁API出错：", err)
-		os.Exit(1)
+		return FundamentalData{}, fmt.Errorf("理杏仁API出错：%w", err)
 	}
+	defer resp.Body.Close()
 	content, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Println("读取数据出错：", err)
-		os.Exit(1)
+		return FundamentalData{}, fmt.Errorf("读取数据出错：%w", err)
 	}
 	var d FunResponseData
 	if err := json.Unmarshal(content, &d); err != nil {
-		fmt.Println("基本面数据格式化错误：", err)
-		os.Exit(1)
+		return FundamentalData{}, fmt.Errorf("基本面数据格式化错误：%w", err)
 	}
-	validate := Analyze{FunData: d}
-	validate.High()
+	if len(d.Data) == 0 {
+		return FundamentalData{}, fmt.Errorf("理杏仁未返回 %s 的基本面数据", code)
+	}
+	l.Cache.Set("fundamental", code, date, content)
+	return d.Data[0], nil
 }
 
-// 经营信息，需要有开始及结束日期，只允许单个股票代码，不支持银行股
-func (l *Lixinger) Industry(code, startDate string) {
-	l.StockCodes = []string{code}
-	l.StartDate = startDate
-	l.EndDate = time.Now().Format("2006-01-02")
-	l.Metrics = []string{
-		"q.profitStatement.bi.t", "q.balanceSheet.ar.t",
-		"q.balanceSheet.s.t", "q.balanceSheet.tca_tcl_r.t",
+// GetFinancialReports 从理杏仁拉取指定区间的季度经营数据，只允许单个股票代码，不支持银行股
+func (l *Lixinger) GetFinancialReports(code, startDate, endDate string) ([]IndustryData, error) {
+	// 缓存键只取startDate：endDate传入的是当天日期，年报数据本身很少变化，
+	// 以endDate入键会导致缓存每天都失效，DefaultIndustryTTL形同虚设。
+	cacheKey := startDate
+	if cached, ok := l.Cache.Get("industry", code, cacheKey, DefaultIndustryTTL); ok {
+		var d IndResponseData
+		if err := json.Unmarshal(cached, &d); err == nil {
+			return d.Data, nil
+		}
+	}
+
+	req := Lixinger{
+		StockCodes: []string{code},
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Token:      l.Token,
+		Metrics: []string{
+			"q.profitStatement.bi.t", "q.balanceSheet.ar.t",
+			"q.balanceSheet.s.t", "q.balanceSheet.tca_tcl_r.t",
+		},
 	}
-	body := l.Format()
+	body := req.Format()
 	if body == nil {
-		fmt.Println("POST数据格式化错误")
+		return nil, fmt.Errorf("POST数据格式化错误")
 	}
+	l.Limiter.wait()
 	resp, err := http.Post(IndustryURL, ContentType, body)
-	defer resp.Body.Close()
 	if err != nil {
-		fmt.Println("理杏仁API出错：", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("理杏仁API出错：%w", err)
 	}
+	defer resp.Body.Close()
 	content, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Println("读取数据出错：", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("读取数据出错：%w", err)
 	}
 	var d IndResponseData
 	if err := json.Unmarshal(content, &d); err != nil {
-		fmt.Println("经营信息数据格式化出错：", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("经营信息数据格式化出错：%w", err)
 	}
-	validate := Analyze{IndData: d}
-	validate.Remove()
-	validate.Compute()
-	validate.Three()
-	validate.One()
-	validate.Two()
+	l.Cache.Set("industry", code, cacheKey, content)
+	return d.Data, nil
+}
+
+// GetGrahamMetrics 从理杏仁拉取计算格雷厄姆内在价值所需的每股指标
+func (l *Lixinger) GetGrahamMetrics(code, date string) (GrahamMetrics, error) {
+	if cached, ok := l.Cache.Get("graham", code, date, l.Cache.fundamentalTTL()); ok {
+		var d GrahamResponseData
+		if err := json.Unmarshal(cached, &d); err == nil && len(d.Data) > 0 {
+			return d.Data[0], nil
+		}
+	}
+
+	req := Lixinger{
+		StockCodes: []string{code},
+		Date:       date,
+		Token:      l.Token,
+		Metrics:    []string{"eps_ttm", "bps", "growth_rate"},
+	}
+	body := req.Format()
+	if body == nil {
+		return GrahamMetrics{}, fmt.Errorf("POST数据格式化错误")
+	}
+	l.Limiter.wait()
+	resp, err := http.Post(FundomentalURL, ContentType, body)
+	if err != nil {
+		return GrahamMetrics{}, fmt.Errorf("理杏仁API出错：%w", err)
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return GrahamMetrics{}, fmt.Errorf("读取数据出错：%w", err)
+	}
+	var d GrahamResponseData
+	if err := json.Unmarshal(content, &d); err != nil {
+		return GrahamMetrics{}, fmt.Errorf("每股指标数据格式化错误：%w", err)
+	}
+	if len(d.Data) == 0 {
+		return GrahamMetrics{}, fmt.Errorf("理杏仁未返回 %s 的每股指标数据", code)
+	}
+	l.Cache.Set("graham", code, date, content)
+	return d.Data[0], nil
 }
 
 func (l *Lixinger) Format() *bytes.Buffer {
@@ -153,130 +181,290 @@ func (l *Lixinger) Format() *bytes.Buffer {
 type Analyze struct {
 	IndData IndResponseData
 	FunData FunResponseData
+	Years   []YearlyFinancial
 }
 
-// 不对银行股做分析
-func (a *Analyze) Remove() {
-	if a.IndData.Data[0].Industry.CnName == "银行" {
-		fmt.Println("不支持银行股的筛选验证")
-		os.Exit(0)
+// YearlyFinancial 是某一年年报的关键经营数据，用于小熊定理的滑动窗口判断
+type YearlyFinancial struct {
+	Year         string
+	Bi           float64 // 营业收入
+	Ar           float64 // 应收账款
+	S            float64 // 存货
+	CurrentRatio float64 // 流动比率
+}
+
+// ScreenResult 是单个股票的筛选结果，便于批量调用方收集而无需中断进程
+type ScreenResult struct {
+	Code           string
+	Name           string
+	Industry       string
+	Pe             float64
+	PePos10        float64
+	Pb             float64
+	PbPos10        float64
+	IntrinsicValue float64
+	MarginOfSafety float64
+	Passed         bool
+	PassedRules    []string
+	FailedRules    []string
+	Details        []string
+	RiskTitles     []string
+}
+
+// Screen 依次执行银行股剔除、PE/PB估值、格雷厄姆内在价值及小熊定理筛选，
+// 汇总为结构化的ScreenResult，过程中不调用os.Exit，便于批量调用方收集结果。
+// skipValuation为true时（数据源不支持估值分位/每股指标数据，如Eastmoney），
+// 跳过PE/PB估值及格雷厄姆检查，只记录一条说明，不计入通过/不合格项。
+func (a *Analyze) Screen(code string, gm GrahamMetrics, bondYield, safetyMargin float64, years int, skipValuation bool) ScreenResult {
+	result := ScreenResult{Code: code, Passed: true}
+	if len(a.FunData.Data) > 0 {
+		fd := a.FunData.Data[0]
+		result.Name = fd.StockCnName
+		result.Industry = fd.Industry.CnName
+		result.Pe = fd.Pe_ttm
+		result.PePos10 = fd.Pe_ttm_pos10
+		result.Pb = fd.Pb
+		result.PbPos10 = fd.Pb_pos10
+	}
+
+	if ok, detail := a.Remove(); !ok {
+		result.Passed = false
+		result.FailedRules = append(result.FailedRules, "银行股")
+		result.Details = append(result.Details, detail)
+		return result
+	}
+	result.PassedRules = append(result.PassedRules, "银行股")
+
+	if skipValuation {
+		result.Details = append(result.Details, "数据源不支持估值分位/每股指标数据，已跳过PE/PB估值与格雷厄姆检查")
+	} else {
+		ok, detail := a.High()
+		if !ok {
+			result.Passed = false
+			result.FailedRules = append(result.FailedRules, "PE/PB估值过高")
+		} else {
+			result.PassedRules = append(result.PassedRules, "PE/PB估值过高")
+		}
+		result.Details = append(result.Details, detail)
+
+		price := result.Pe * gm.EpsTtm
+		intrinsicValue, marginOfSafety, ok, detail := a.Graham(gm, price, bondYield, safetyMargin)
+		result.IntrinsicValue = intrinsicValue
+		result.MarginOfSafety = marginOfSafety
+		if !ok {
+			result.Passed = false
+			result.FailedRules = append(result.FailedRules, "安全边际不足")
+		} else {
+			result.PassedRules = append(result.PassedRules, "安全边际不足")
+		}
+		result.Details = append(result.Details, detail)
 	}
+
+	a.Compute()
+	xiong := a.Xiong(code, years)
+	result.PassedRules = append(result.PassedRules, xiong.PassedRules...)
+	result.FailedRules = append(result.FailedRules, xiong.FailedRules...)
+	result.Details = append(result.Details, xiong.Details...)
+	if !xiong.Passed {
+		result.Passed = false
+	}
+
+	return result
 }
 
-func (a *Analyze) High() {
+// Remove 不对银行股做分析
+func (a *Analyze) Remove() (bool, string) {
+	if len(a.IndData.Data) > 0 && a.IndData.Data[0].Industry.CnName == "银行" {
+		return false, "不支持银行股的筛选验证"
+	}
+	return true, "非银行股，通过行业筛选"
+}
+
+// High 检验PE/PB估值是否过高
+func (a *Analyze) High() (bool, string) {
 	for _, fd := range a.FunData.Data {
 		if Smaller(0.50000, fd.Pb_pos10) {
-			msg := fmt.Sprintf("当前PB: %f，PB分位点：%f，大于50%，估值过高，不合格", fd.Pb, fd.Pb_pos10)
-			fmt.Println(msg)
-			os.Exit(0)
+			return false, fmt.Sprintf("当前PB: %f，PB分位点：%f，大于50%%，估值过高，不合格", fd.Pb, fd.Pb_pos10)
 		}
 		if Smaller(0.50000, fd.Pe_ttm_pos10) {
-			msg := fmt.Sprintf("当前PE: %f，PE分位点：%f，大于50%，估值过高，不合格", fd.Pe_ttm, fd.Pe_ttm_pos10)
-			fmt.Println(msg)
-			os.Exit(0)
+			return false, fmt.Sprintf("当前PE: %f，PE分位点：%f，大于50%%，估值过高，不合格", fd.Pe_ttm, fd.Pe_ttm_pos10)
 		}
-		msg := fmt.Sprintf(`当前PE: %f，最近10年PE分位点：%f
-当前PB: %f, 最近10年PB分位点: %f`, fd.Pe_ttm, fd.Pe_ttm_pos10, fd.Pb, fd.Pb_pos10)
-		fmt.Println(msg)
 	}
+	msg := fmt.Sprintf("当前PE/PB分位点均未超过50%%，估值检测通过")
+	return true, msg
+}
+
+// Graham 计算格雷厄姆数及修正内在价值公式，与当前股价比较评估安全边际
+func (a *Analyze) Graham(gm GrahamMetrics, price, bondYield, safetyMargin float64) (intrinsicValue, marginOfSafety float64, passed bool, detail string) {
+	grahamNumber := math.Sqrt(22.5 * gm.EpsTtm * gm.Bps)
+	// 经典公式里的g和Y都是整数百分比（如g=12、Y=4.4），而GrowthRate是0-1的小数，
+	// 换算为百分比后再代入，否则2*g会被0-1的小数压成接近0，低估成长股的内在价值。
+	intrinsicValue = gm.EpsTtm * (8.5 + 2*gm.GrowthRate*100) * 4.4 / bondYield
+	marginOfSafety = (intrinsicValue - price) / intrinsicValue * 100
+
+	msg := fmt.Sprintf(`格雷厄姆数: %f
+内在价值(修正公式): %f
+当前股价: %f
+安全边际: %.2f%%`, grahamNumber, intrinsicValue, price, marginOfSafety)
+
+	if price > intrinsicValue*(1-safetyMargin) {
+		return intrinsicValue, marginOfSafety, false, msg + "\n当前股价高于内在价值的安全边际线，估值过高，不合格"
+	}
+	return intrinsicValue, marginOfSafety, true, msg + "\n格雷厄姆内在价值检测通过"
 }
 
+// Compute 从年报（month==12）数据中构建按年份排序的YearlyFinancials
 func (a *Analyze) Compute() {
+	a.Years = a.Years[:0]
 	for _, fd := range a.IndData.Data {
 		d := strings.Split(fd.Date, "-")
-		if d[1] == "12" {
-			if d[0] == "2017" {
-				bi2017 = fd.Q.ProfitStatement.Bi.T
-				ar2017 = fd.Q.BalanceSheet.Ar.T
-				s2017 = fd.Q.BalanceSheet.S.T
-			} else if d[0] == "2016" {
-				bi2016 = fd.Q.ProfitStatement.Bi.T
-				ar2016 = fd.Q.BalanceSheet.Ar.T
-				s2016 = fd.Q.BalanceSheet.S.T
-			} else if d[0] == "2015" {
-				bi2015 = fd.Q.ProfitStatement.Bi.T
-				ar2015 = fd.Q.BalanceSheet.Ar.T
-				s2015 = fd.Q.BalanceSheet.S.T
-			} else if d[0] == "2014" {
-				bi2014 = fd.Q.ProfitStatement.Bi.T
-				ar2014 = fd.Q.BalanceSheet.Ar.T
-				s2014 = fd.Q.BalanceSheet.S.T
-			}
+		if len(d) < 2 || d[1] != "12" {
+			continue
 		}
-		bigrowth1 = bi2017 - bi2016
-		bigrowth2 = bi2016 - bi2015
-		bigrowth3 = bi2015 - bi2014
-
-		argrowth1 = ar2017 - ar2016
-		argrowth2 = ar2016 - ar2015
-		argrowth3 = ar2015 - ar2014
+		a.Years = append(a.Years, YearlyFinancial{
+			Year:         d[0],
+			Bi:           fd.Q.ProfitStatement.Bi.T,
+			Ar:           fd.Q.BalanceSheet.Ar.T,
+			S:            fd.Q.BalanceSheet.S.T,
+			CurrentRatio: fd.Q.BalanceSheet.Tca_tcl_r.T,
+		})
+	}
+	sort.Slice(a.Years, func(i, j int) bool { return a.Years[i].Year < a.Years[j].Year })
+}
 
-		sgrowth1 = s2017 - s2016
-		sgrowth2 = s2016 - s2015
-		sgrowth3 = s2015 - s2014
+// window 返回最近years年的年报数据，数据不足years年时返回全部
+func (a *Analyze) window(years int) []YearlyFinancial {
+	if years <= 0 {
+		years = DefaultWindowYears
+	}
+	if len(a.Years) <= years {
+		return a.Years
 	}
+	return a.Years[len(a.Years)-years:]
 }
 
-// 连续两年应收账款增长 > 营业收入增长，剔除
-func (a *Analyze) One() {
-	if Smaller(bigrowth3, argrowth3) {
-		if Smaller(bigrowth2, argrowth2) {
-			msg := fmt.Sprintf("15年、16年连续两年不符合小熊定理一，不合格")
-			fmt.Println(msg)
-			os.Exit(0)
-		}
-	} else if Smaller(bigrowth2, argrowth2) {
-		if Smaller(bigrowth1, argrowth1) {
-			msg := fmt.Sprintf("17年、16年连续两年不符合小熊定理一，不合格")
-			fmt.Println(msg)
-			os.Exit(0)
+// One 连续两年应收账款增长 > 营业收入增长，剔除
+func (a *Analyze) One(years int) (bool, string) {
+	w := a.window(years)
+	for i := 2; i < len(w); i++ {
+		prevBiGrowth, prevArGrowth := w[i-1].Bi-w[i-2].Bi, w[i-1].Ar-w[i-2].Ar
+		curBiGrowth, curArGrowth := w[i].Bi-w[i-1].Bi, w[i].Ar-w[i-1].Ar
+		if Smaller(prevBiGrowth, prevArGrowth) && Smaller(curBiGrowth, curArGrowth) {
+			return false, fmt.Sprintf("%s年、%s年连续两年不符合小熊定理一，不合格", w[i-1].Year, w[i].Year)
 		}
 	}
-	fmt.Println("小熊定理一检测通过")
+	return true, "小熊定理一检测通过"
 }
 
-// 连续两年存货增长 > 营业收入增长，剔除
-func (a *Analyze) Two() {
-	if Smaller(bigrowth3, sgrowth3) {
-		if Smaller(bigrowth2, sgrowth2) {
-			msg := fmt.Sprintf("15年、16年连续两年不符合小熊定理二，不合格")
-			fmt.Println(msg)
-			os.Exit(0)
+// Two 连续两年存货增长 > 营业收入增长，剔除
+func (a *Analyze) Two(years int) (bool, string) {
+	w := a.window(years)
+	for i := 2; i < len(w); i++ {
+		prevBiGrowth, prevSGrowth := w[i-1].Bi-w[i-2].Bi, w[i-1].S-w[i-2].S
+		curBiGrowth, curSGrowth := w[i].Bi-w[i-1].Bi, w[i].S-w[i-1].S
+		if Smaller(prevBiGrowth, prevSGrowth) && Smaller(curBiGrowth, curSGrowth) {
+			return false, fmt.Sprintf("%s年、%s年连续两年不符合小熊定理二，不合格", w[i-1].Year, w[i].Year)
 		}
-	} else if Smaller(bigrowth2, sgrowth2) {
-		if Smaller(bigrowth1, sgrowth1) {
-			msg := fmt.Sprintf("17年、16年连续两年不符合小熊定理二，不合格")
-			fmt.Println(msg)
-			os.Exit(0)
+	}
+	return true, "小熊定理二检测通过"
+}
+
+// Three 流动比率 < 1 应该予以剔除
+func (a *Analyze) Three(years int) (bool, string) {
+	for _, y := range a.window(years) {
+		if Smaller(y.CurrentRatio, 1.0000) {
+			return false, fmt.Sprintf("%s年度的流动比率小于1，不符合白马股条件，不合格", y.Year)
 		}
 	}
-	fmt.Println("小熊定理二检测通过")
+	return true, "小熊定理三检测通过"
 }
 
-// 流动比率 < 1 应该予以剔除， 连续4年的
-func (a *Analyze) Three() {
-	for _, fd := range a.IndData.Data {
-		if strings.Split(fd.Date, "-")[1] == "12" {
-			r := fd.Q.BalanceSheet.Tca_tcl_r.T
-			if Smaller(r, 1.0000) {
-				msg := fmt.Sprintf("%s年度的流动比率小于1，不符合白马股条件，不合格", strings.Split(fd.Date, "-")[0])
-				fmt.Println(msg)
-				os.Exit(0)
-			}
+// Xiong 依次执行小熊定理一、二、三，汇总为结构化的筛选结果，不再调用os.Exit
+func (a *Analyze) Xiong(code string, years int) ScreenResult {
+	result := ScreenResult{Code: code, Passed: true}
+	checks := []func(int) (bool, string){a.One, a.Two, a.Three}
+	names := []string{"小熊定理一", "小熊定理二", "小熊定理三"}
+	for i, check := range checks {
+		ok, detail := check(years)
+		result.Details = append(result.Details, detail)
+		if !ok {
+			result.Passed = false
+			result.FailedRules = append(result.FailedRules, names[i])
+		} else {
+			result.PassedRules = append(result.PassedRules, names[i])
 		}
 	}
-	fmt.Println("小熊定理三检测通过")
+	return result
 }
 
 func Smaller(a, b float64) bool {
 	return math.Max(a, b) == b && math.Abs(a-b) > Min
 }
 
+// screenOne 拉取单只股票的基本面、每股指标及经营数据，并执行完整的白马股筛选。
+// 公告风险筛选在所有理杏仁请求之前执行，命中风险关键词时提前返回，不消耗理杏仁额度。
+func screenOne(data DataSource, code, startDate string, bondYield, safetyMargin float64, years int, riskKeywords []string) (ScreenResult, error) {
+	result := ScreenResult{Code: code, Passed: true}
+
+	titles, err := fetchAnnouncementTitles(code, RiskNoticeDays)
+	if err != nil {
+		return ScreenResult{}, err
+	}
+	ok, matched, detail := RiskNotice(titles, riskKeywords)
+	result.Details = append(result.Details, detail)
+	if !ok {
+		result.Passed = false
+		result.FailedRules = append(result.FailedRules, "公告风险")
+		result.RiskTitles = matched
+		return result, nil
+	}
+	result.PassedRules = append(result.PassedRules, "公告风险")
+
+	skipValuation := false
+	fd, err := data.GetFundamentals(code, previousDay())
+	if err != nil {
+		if !errors.Is(err, ErrUnsupported) {
+			return ScreenResult{}, err
+		}
+		skipValuation = true
+	}
+	gm, err := data.GetGrahamMetrics(code, previousDay())
+	if err != nil {
+		if !errors.Is(err, ErrUnsupported) {
+			return ScreenResult{}, err
+		}
+		skipValuation = true
+	}
+	reports, err := data.GetFinancialReports(code, startDate, time.Now().Format("2006-01-02"))
+	if err != nil {
+		return ScreenResult{}, err
+	}
+	validate := Analyze{
+		FunData: FunResponseData{Data: []FundamentalData{fd}},
+		IndData: IndResponseData{Data: reports},
+	}
+	screened := validate.Screen(code, gm, bondYield, safetyMargin, years, skipValuation)
+	screened.PassedRules = append(result.PassedRules, screened.PassedRules...)
+	screened.Details = append(result.Details, screened.Details...)
+	return screened, nil
+}
+
 func main() {
 	help := flag.Bool("help", false, "获取帮助")
 	token := flag.String("token", "5e9f7dc2-cc65-4e60-a8ba-47d13e401b7a", "理杏仁API token，可以从理杏仁网站获取")
 	stockCode := flag.String("code", "000651", "股票代码")
 	startYear := flag.String("year", "2014", "开始年份")
+	source := flag.String("source", "lixinger", "数据源：lixinger（需要token）或 eastmoney（免费，无需token）")
+	bondYield := flag.Float64("bondyield", 4.0, "当前AAA企业债到期收益率，用于格雷厄姆内在价值计算")
+	safety := flag.Float64("safety", 0.3, "格雷厄姆内在价值的安全边际比例")
+	years := flag.Int("years", DefaultWindowYears, "小熊定理滑动窗口的年数")
+	codes := flag.String("codes", "", "批量筛选的股票代码，逗号分隔，或@文件路径（每行一个代码）")
+	workers := flag.Int("workers", DefaultWorkers, "批量筛选的并发worker数")
+	out := flag.String("out", "", "批量筛选结果的输出文件（.csv或.json），为空则打印到终端")
+	cacheDir := flag.String("cache-dir", defaultCacheDir(), "理杏仁响应缓存目录")
+	cacheTTL := flag.Duration("cache-ttl", DefaultFundamentalTTL, "基本面/每股指标缓存的有效期，年度经营数据固定缓存90天")
+	refresh := flag.Bool("refresh", false, "忽略缓存，强制重新请求理杏仁API")
+	riskKeywords := flag.String("risk-keywords", "", "公告风险筛选的关键词，逗号分隔，为空则使用内置默认关键词")
 	flag.Parse()
 
 	if *help {
@@ -284,20 +472,69 @@ func main() {
 		return
 	}
 
+	cache := NewCache(*cacheDir, *cacheTTL, *refresh)
+
+	var data DataSource
+	switch *source {
+	case "eastmoney":
+		data = &Eastmoney{}
+	case "lixinger":
+		data = &Lixinger{Token: *token, Cache: cache, Limiter: newRateLimiter(LixingerRateLimit)}
+	default:
+		fmt.Printf("不支持的数据源：%s\n", *source)
+		return
+	}
+	startDate := fmt.Sprintf("%s-01-01", *startYear)
+
+	var keywords []string
+	if *riskKeywords != "" {
+		for _, kw := range strings.Split(*riskKeywords, ",") {
+			if kw = strings.TrimSpace(kw); kw != "" {
+				keywords = append(keywords, kw)
+			}
+		}
+	}
+
+	if *codes != "" {
+		codeList, err := parseCodes(*codes)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := RunBatch(data, codeList, *workers, startDate, *bondYield, *safety, *years, *out, keywords); err != nil {
+			fmt.Println(err)
+		}
+		hits, misses := cache.Stats()
+		fmt.Printf("缓存命中: %d，缓存未命中: %d\n", hits, misses)
+		return
+	}
+
 	code, err := strconv.Atoi(*stockCode)
 	if err != nil || code >= 999999 || code <= 0 {
 		fmt.Println("股票代码错误")
 		return
 	}
-	startDate := fmt.Sprintf("%s-01-01", *startYear)
 
-	data := Lixinger{
-		Token:   *token,
-		Metrics: []string{"pb", "pb_pos10", "pb_pos_all"},
+	fmt.Printf("开始对该个股 %s 进行白马组合分析（数据源：%s）\n%c[1;40;31m分析不包含基本面分析，请自行剔除基本面转坏的个股，不支持银行股分析%c[0m\n", *stockCode, *source, 0x1B, 0x1B)
+
+	result, err := screenOne(data, *stockCode, startDate, *bondYield, *safety, *years, keywords)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, detail := range result.Details {
+		fmt.Println(detail)
 	}
-	fmt.Printf("开始对该个股 %s 进行白马组合分析\n%c[1;40;31m分析不包含基本面分析，请自行剔除基本面转坏的个股，不支持银行股分析%c[0m\n", *stockCode, 0x1B, 0x1B)
-	data.Fundamental(*stockCode)
-	data.Industry(*stockCode, startDate)
+	if !result.Passed {
+		fmt.Printf("不合格项：%s\n", strings.Join(result.FailedRules, "、"))
+		os.Exit(0)
+	}
+	fmt.Println("白马组合分析全部通过")
+}
+
+// previousDay 返回昨天的日期（估值数据通常 T+1 才更新）
+func previousDay() string {
+	return time.Now().AddDate(0, 0, -1).Format("2006-01-02")
 }
 
 type Total struct {
@@ -359,6 +596,12 @@ type FunResponseData struct {
 	Data []FundamentalData `json:"data"`
 }
 
+type GrahamResponseData struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data []GrahamMetrics `json:"data"`
+}
+
 func Usage() {
 	fmt.Println(`Usage:
 
@@ -372,5 +615,27 @@ choseStock [option]
     	理杏仁API token，可以从理杏仁网站获取 (default "5e9f7dc2-cc65-4e60-a8ba-47d13e401b7a")
   -year string
     	开始年份 (default "2014")
+  -source string
+    	数据源：lixinger（需要token）或 eastmoney（免费，无需token） (default "lixinger")
+  -bondyield float
+    	当前AAA企业债到期收益率，用于格雷厄姆内在价值计算 (default 4)
+  -safety float
+    	格雷厄姆内在价值的安全边际比例 (default 0.3)
+  -years int
+    	小熊定理滑动窗口的年数 (default 4)
+  -codes string
+    	批量筛选的股票代码，逗号分隔，或@文件路径（每行一个代码）
+  -workers int
+    	批量筛选的并发worker数 (default 4)
+  -out string
+    	批量筛选结果的输出文件（.csv或.json），为空则打印到终端
+  -cache-dir string
+    	理杏仁响应缓存目录 (default "~/.chosestock/cache")
+  -cache-ttl duration
+    	基本面/每股指标缓存的有效期，年度经营数据固定缓存90天 (default 24h0m0s)
+  -refresh bool
+    	忽略缓存，强制重新请求理杏仁API
+  -risk-keywords string
+    	公告风险筛选的关键词，逗号分隔，为空则使用内置默认关键词
 	`)
 }
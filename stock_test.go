@@ -0,0 +1,220 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// mockDataSource 是测试用的DataSource实现，便于脱离真实理杏仁/东方财富API验证筛选逻辑
+type mockDataSource struct {
+	fundamental FundamentalData
+	graham      GrahamMetrics
+	reports     []IndustryData
+}
+
+func (m *mockDataSource) GetFundamentals(code, date string) (FundamentalData, error) {
+	return m.fundamental, nil
+}
+
+func (m *mockDataSource) GetFinancialReports(code, startDate, endDate string) ([]IndustryData, error) {
+	return m.reports, nil
+}
+
+func (m *mockDataSource) GetGrahamMetrics(code, date string) (GrahamMetrics, error) {
+	return m.graham, nil
+}
+
+func yearlyFinancials(years ...float64) []YearlyFinancial {
+	// years是每年的营业收入，按顺序生成年份递增的YearlyFinancial，其余指标保持通过筛选的默认值
+	var out []YearlyFinancial
+	for i, bi := range years {
+		out = append(out, YearlyFinancial{
+			Year:         string(rune('0'+i)) + "年",
+			Bi:           bi,
+			Ar:           0,
+			S:            0,
+			CurrentRatio: 2,
+		})
+	}
+	return out
+}
+
+func TestAnalyzeWindow(t *testing.T) {
+	cases := []struct {
+		name  string
+		years []float64
+		n     int
+		want  int
+	}{
+		{"数据不足N年时返回全部", []float64{1, 2}, 4, 2},
+		{"数据恰好N年时返回全部", []float64{1, 2, 3, 4}, 4, 4},
+		{"数据超过N年时只取最近N年", []float64{1, 2, 3, 4, 5}, 4, 4},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := &Analyze{Years: yearlyFinancials(c.years...)}
+			w := a.window(c.n)
+			if len(w) != c.want {
+				t.Fatalf("window(%d) 返回 %d 条，期望 %d 条", c.n, len(w), c.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeOne(t *testing.T) {
+	t.Run("数据不足三年时直接通过", func(t *testing.T) {
+		a := &Analyze{Years: []YearlyFinancial{
+			{Year: "2020", Bi: 100, Ar: 10},
+			{Year: "2021", Bi: 110, Ar: 20},
+		}}
+		ok, _ := a.One(4)
+		if !ok {
+			t.Fatal("数据不足三年时应直接通过小熊定理一")
+		}
+	})
+
+	t.Run("连续两年应收账款增长超过营业收入增长时不合格", func(t *testing.T) {
+		a := &Analyze{Years: []YearlyFinancial{
+			{Year: "2019", Bi: 100, Ar: 10},
+			{Year: "2020", Bi: 110, Ar: 30}, // Ar增长20 > Bi增长10
+			{Year: "2021", Bi: 120, Ar: 60}, // Ar增长30 > Bi增长10
+		}}
+		ok, _ := a.One(4)
+		if ok {
+			t.Fatal("连续两年应收账款增长超过营业收入增长时应判定不合格")
+		}
+	})
+
+	t.Run("恰好窗口边界内数据也应参与判断", func(t *testing.T) {
+		a := &Analyze{Years: []YearlyFinancial{
+			{Year: "2019", Bi: 100, Ar: 10},
+			{Year: "2020", Bi: 110, Ar: 30},
+			{Year: "2021", Bi: 120, Ar: 60},
+		}}
+		ok, _ := a.One(3)
+		if ok {
+			t.Fatal("窗口恰好等于数据年数时仍应检测到不合格")
+		}
+	})
+}
+
+func TestAnalyzeTwo(t *testing.T) {
+	t.Run("存货增长未连续两年超过营业收入增长时通过", func(t *testing.T) {
+		a := &Analyze{Years: []YearlyFinancial{
+			{Year: "2019", Bi: 100, S: 10},
+			{Year: "2020", Bi: 150, S: 30},
+			{Year: "2021", Bi: 200, S: 40},
+		}}
+		ok, _ := a.Two(4)
+		if !ok {
+			t.Fatal("存货增长未连续两年超过营业收入增长时应通过小熊定理二")
+		}
+	})
+
+	t.Run("连续两年存货增长超过营业收入增长时不合格", func(t *testing.T) {
+		a := &Analyze{Years: []YearlyFinancial{
+			{Year: "2019", Bi: 100, S: 10},
+			{Year: "2020", Bi: 105, S: 30},
+			{Year: "2021", Bi: 110, S: 60},
+		}}
+		ok, _ := a.Two(4)
+		if ok {
+			t.Fatal("连续两年存货增长超过营业收入增长时应判定不合格")
+		}
+	})
+}
+
+func TestAnalyzeThree(t *testing.T) {
+	t.Run("流动比率均不小于1时通过", func(t *testing.T) {
+		a := &Analyze{Years: []YearlyFinancial{
+			{Year: "2020", CurrentRatio: 1.2},
+			{Year: "2021", CurrentRatio: 1.5},
+		}}
+		ok, _ := a.Three(4)
+		if !ok {
+			t.Fatal("流动比率均不小于1时应通过小熊定理三")
+		}
+	})
+
+	t.Run("窗口内任意一年流动比率小于1时不合格", func(t *testing.T) {
+		a := &Analyze{Years: []YearlyFinancial{
+			{Year: "2020", CurrentRatio: 1.2},
+			{Year: "2021", CurrentRatio: 0.9},
+		}}
+		ok, _ := a.Three(4)
+		if ok {
+			t.Fatal("窗口内存在流动比率小于1的年份时应判定不合格")
+		}
+	})
+}
+
+func TestAnalyzeGraham(t *testing.T) {
+	a := &Analyze{}
+	gm := GrahamMetrics{EpsTtm: 1.0, Bps: 5.0, GrowthRate: 0.12} // 12%增长率，以0-1小数表示
+	bondYield := 4.4
+	safetyMargin := 0.3
+
+	t.Run("GrowthRate按0-1小数换算为整数百分比参与公式", func(t *testing.T) {
+		// 8.5 + 2*12 = 32.5，换算错误（按0.12直接代入）则会得到 8.5+2*0.12=8.74，
+		// 本测试锁定GrowthRate是0-1小数、需要*100换算后才能代入经典公式的约定。
+		wantIntrinsicValue := gm.EpsTtm * (8.5 + 2*12) * 4.4 / bondYield
+		intrinsicValue, _, _, _ := a.Graham(gm, 0, bondYield, safetyMargin)
+		if math.Abs(intrinsicValue-wantIntrinsicValue) > 1e-9 {
+			t.Fatalf("内在价值 = %f，期望按整数百分比换算后得到 %f（说明GrowthRate未按0-1小数处理）", intrinsicValue, wantIntrinsicValue)
+		}
+	})
+
+	t.Run("股价低于安全边际线时合格", func(t *testing.T) {
+		intrinsicValue, _, _, _ := a.Graham(gm, 0, bondYield, safetyMargin)
+		price := intrinsicValue * (1 - safetyMargin) * 0.9 // 明显低于安全边际线
+		_, _, ok, _ := a.Graham(gm, price, bondYield, safetyMargin)
+		if !ok {
+			t.Fatal("股价明显低于安全边际线时应判定合格")
+		}
+	})
+
+	t.Run("股价高于安全边际线时不合格", func(t *testing.T) {
+		intrinsicValue, _, _, _ := a.Graham(gm, 0, bondYield, safetyMargin)
+		price := intrinsicValue * (1 - safetyMargin) * 1.1 // 明显高于安全边际线
+		_, _, ok, _ := a.Graham(gm, price, bondYield, safetyMargin)
+		if ok {
+			t.Fatal("股价明显高于安全边际线时应判定不合格")
+		}
+	})
+
+	t.Run("股价恰好等于安全边际线时合格（严格大于才不合格）", func(t *testing.T) {
+		intrinsicValue, _, _, _ := a.Graham(gm, 0, bondYield, safetyMargin)
+		price := intrinsicValue * (1 - safetyMargin)
+		_, _, ok, _ := a.Graham(gm, price, bondYield, safetyMargin)
+		if !ok {
+			t.Fatal("股价恰好等于安全边际线时应判定合格，Graham用的是严格大于判断")
+		}
+	})
+}
+
+func TestScreenOneWithMockDataSource(t *testing.T) {
+	// 验证DataSource抽象可脱离真实API：用mock实现驱动screenOne这一真正的集成点，
+	// 公告抓取替换为不访问网络的桩实现。
+	origFetch := fetchAnnouncementTitles
+	fetchAnnouncementTitles = func(code string, days int) ([]string, error) {
+		return []string{"2023年年度报告"}, nil
+	}
+	defer func() { fetchAnnouncementTitles = origFetch }()
+
+	var ds DataSource = &mockDataSource{
+		fundamental: FundamentalData{Pb_pos10: 0.2, Pe_ttm_pos10: 0.2},
+		graham:      GrahamMetrics{EpsTtm: 1.0, Bps: 5.0, GrowthRate: 0.1},
+		reports: []IndustryData{
+			{Date: "2020-12-31", Q: Q{BalanceSheet: BalanceSheet{Tca_tcl_r: Total{T: 2}}}},
+			{Date: "2021-12-31", Q: Q{BalanceSheet: BalanceSheet{Tca_tcl_r: Total{T: 2}}}},
+		},
+	}
+
+	result, err := screenOne(ds, "000651", "2020-01-01", 4.0, 0.3, DefaultWindowYears, nil)
+	if err != nil {
+		t.Fatalf("screenOne 不应返回错误：%v", err)
+	}
+	if !result.Passed {
+		t.Fatalf("使用低分位PE/PB、合理安全边际且无风险公告的mock数据时应判定合格，实际：%+v", result.FailedRules)
+	}
+}